@@ -0,0 +1,82 @@
+// Package api implements the shared HTTP envelope CFSSL's API
+// handlers respond with.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	cferr "github.com/cloudflare/cfssl/errors"
+	"github.com/cloudflare/cfssl/log"
+)
+
+// ResponseMessage is a single error or informational message returned
+// alongside a Response.
+type ResponseMessage struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Response is the envelope every CFSSL API endpoint replies with.
+type Response struct {
+	Success  bool              `json:"success"`
+	Result   interface{}       `json:"result,omitempty"`
+	Errors   []ResponseMessage `json:"errors"`
+	Messages []ResponseMessage `json:"messages"`
+}
+
+// HTTPRequestHandler is implemented by an endpoint's business logic;
+// Handle writes directly to w on success and returns an error
+// (ideally a *cferr.Error) on failure, letting HTTPHandler translate
+// it into the Response envelope.
+type HTTPRequestHandler interface {
+	Handle(w http.ResponseWriter, r *http.Request) error
+}
+
+// HTTPHandler adapts an HTTPRequestHandler into an http.Handler,
+// rejecting methods not in Methods and translating a returned error
+// into a failed Response.
+type HTTPHandler struct {
+	Handler HTTPRequestHandler
+	Methods []string
+}
+
+// ServeHTTP implements http.Handler.
+func (h HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	allowed := len(h.Methods) == 0
+	for _, m := range h.Methods {
+		if r.Method == m {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.Handler.Handle(w, r); err != nil {
+		log.Errorf("api: request failed: %s", err)
+		sendError(w, err)
+	}
+}
+
+func sendError(w http.ResponseWriter, err error) {
+	msg := ResponseMessage{Message: err.Error()}
+	if cfErr, ok := err.(*cferr.Error); ok {
+		msg.Code = cfErr.ErrorCode
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(Response{
+		Success: false,
+		Errors:  []ResponseMessage{msg},
+	})
+}
+
+// SendResponse writes a successful Response wrapping result to w.
+func SendResponse(w http.ResponseWriter, result interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(Response{Success: true, Result: result})
+}
@@ -0,0 +1,97 @@
+// Package renew implements an HTTP handler for certificate renewal
+// requests from self-renewing mTLS clients (see signer/remote.Signer.Renew
+// and ca/renew).
+package renew
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/cfssl/api"
+	"github.com/cloudflare/cfssl/certdb"
+	cferr "github.com/cloudflare/cfssl/errors"
+	"github.com/cloudflare/cfssl/log"
+	"github.com/cloudflare/cfssl/revoke"
+	"github.com/cloudflare/cfssl/signer"
+)
+
+// Handler implements an api.HTTPRequestHandler for the renewal
+// endpoint. A client proves its identity by presenting, as a TLS
+// client certificate, the certificate it wishes to renew: no CSR or
+// auth token is required, since possession of the still-valid private
+// key is the proof. The handler re-issues under the same profile and
+// from the same CSR the original certificate was issued with, as
+// recorded in certdb.
+type Handler struct {
+	Signer signer.Signer
+	DB     certdb.Accessor
+
+	// RenewalWindow is the fraction of a certificate's total
+	// lifetime, counted back from expiry, during which a renewal
+	// request is honored. A renewal is refused until the
+	// certificate has entered the last RenewalWindow fraction of
+	// its life (e.g. RenewalWindow 0.2 opens the window at 80%
+	// of the way to expiry).
+	RenewalWindow float64
+}
+
+// NewHandler returns an HTTP handler serving certificate renewals.
+func NewHandler(s signer.Signer, db certdb.Accessor, renewalWindow float64) http.Handler {
+	return api.HTTPHandler{
+		Handler: &Handler{Signer: s, DB: db, RenewalWindow: renewalWindow},
+		Methods: []string{"POST"},
+	}
+}
+
+// Mount registers the renewal endpoint on mux at the standard CFSSL
+// API path.
+func Mount(mux *http.ServeMux, s signer.Signer, db certdb.Accessor, renewalWindow float64) {
+	mux.Handle("/api/v1/cfssl/renew", NewHandler(s, db, renewalWindow))
+}
+
+// Handle implements api.HTTPRequestHandler.
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) error {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return cferr.New(cferr.APIClientError, cferr.ClientHTTPError)
+	}
+	cert := r.TLS.PeerCertificates[0]
+
+	serial := cert.SerialNumber.String()
+	aki := hex.EncodeToString(cert.AuthorityKeyId)
+
+	records, err := h.DB.GetCertificate(serial, aki)
+	if err != nil || len(records) == 0 {
+		return cferr.New(cferr.CertificateError, cferr.CertificateNotFound)
+	}
+	record := records[0]
+
+	if record.Status == "revoked" {
+		return cferr.New(cferr.CertificateError, cferr.CertificateRevoked)
+	}
+
+	if revoked, ok := revoke.VerifyCertificate(cert); ok && revoked {
+		return cferr.New(cferr.CertificateError, cferr.CertificateRevoked)
+	}
+
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	renewAfter := cert.NotAfter.Add(-time.Duration(float64(lifetime) * h.RenewalWindow))
+	if time.Now().Before(renewAfter) {
+		log.Debugf("renewal request for serial %s is outside the renewal window", serial)
+		return cferr.New(cferr.APIClientError, cferr.InvalidRequest)
+	}
+
+	var req signer.SignRequest
+	if err := json.Unmarshal([]byte(record.Request), &req); err != nil {
+		return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+	req.Profile = record.CAProfile
+
+	certPEM, err := h.Signer.Sign(req)
+	if err != nil {
+		return err
+	}
+
+	return api.SendResponse(w, map[string]string{"certificate": string(certPEM)})
+}
@@ -0,0 +1,140 @@
+package renew
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cfssl/certdb"
+	"github.com/cloudflare/cfssl/config"
+	cferr "github.com/cloudflare/cfssl/errors"
+	"github.com/cloudflare/cfssl/info"
+	"github.com/cloudflare/cfssl/signer"
+)
+
+// selfSigned builds a self-signed leaf with the given lifetime bounds,
+// so renewal-window cases can be expressed as fractions of that
+// lifetime.
+func selfSigned(t *testing.T, notBefore, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "renew-test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+type fakeAccessor struct {
+	record certdb.CertificateRecord
+}
+
+func (f *fakeAccessor) InsertCertificate(cr certdb.CertificateRecord) error { return nil }
+func (f *fakeAccessor) GetCertificate(serial, aki string) ([]certdb.CertificateRecord, error) {
+	return []certdb.CertificateRecord{f.record}, nil
+}
+func (f *fakeAccessor) GetUnexpiredCertificates() ([]certdb.CertificateRecord, error) {
+	return nil, nil
+}
+func (f *fakeAccessor) RevokeCertificate(serial, aki string, reasonCode int) error { return nil }
+
+type fakeSigner struct{}
+
+func (fakeSigner) Info(info.Req) (*info.Resp, error)          { return nil, nil }
+func (fakeSigner) Sign(signer.SignRequest) ([]byte, error)    { return []byte("signed"), nil }
+func (fakeSigner) SigAlgo() x509.SignatureAlgorithm           { return x509.ECDSAWithSHA256 }
+func (fakeSigner) SetPolicy(*config.Signing)                  {}
+func (fakeSigner) SetDBAccessor(certdb.Accessor)              {}
+func (fakeSigner) GetDBAccessor() certdb.Accessor             { return nil }
+func (fakeSigner) Policy() *config.Signing                    { return nil }
+func (fakeSigner) SetReqModifier(func(*http.Request, []byte)) {}
+
+func renewalRequest(cert *x509.Certificate) *http.Request {
+	r := httptest.NewRequest("POST", "/api/v1/cfssl/renew", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	return r
+}
+
+// TestHandleRenewalWindow exercises the "has this certificate entered
+// its last RenewalWindow fraction of life" gate with cases straddling
+// the boundary, rather than just the lifetime midpoint.
+func TestHandleRenewalWindow(t *testing.T) {
+	const window = 0.2
+
+	cases := []struct {
+		name        string
+		elapsedFrac float64 // how far through the cert's life "now" is
+		wantRefused bool
+	}{
+		{"just_issued", 0.0, true},
+		{"halfway", 0.5, true},
+		{"just_before_window_opens", 0.79, true},
+		{"just_after_window_opens", 0.81, false},
+		{"near_expiry", 0.99, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			const lifetime = 100 * time.Hour
+			notBefore := time.Now().Add(-time.Duration(tc.elapsedFrac * float64(lifetime)))
+			notAfter := notBefore.Add(lifetime)
+			cert := selfSigned(t, notBefore, notAfter)
+
+			db := &fakeAccessor{record: certdb.CertificateRecord{
+				Status:    "good",
+				CAProfile: "default",
+				Request:   `{"certificate_request":"csr"}`,
+			}}
+
+			h := &Handler{Signer: fakeSigner{}, DB: db, RenewalWindow: window}
+			w := httptest.NewRecorder()
+			err := h.Handle(w, renewalRequest(cert))
+
+			refused := err != nil
+			if refused != tc.wantRefused {
+				t.Errorf("elapsedFrac=%v: got refused=%v (err=%v), want refused=%v",
+					tc.elapsedFrac, refused, err, tc.wantRefused)
+			}
+			if refused {
+				if _, ok := err.(*cferr.Error); !ok {
+					t.Errorf("elapsedFrac=%v: expected a *cferr.Error, got %v (%T)", tc.elapsedFrac, err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRejectsRevokedRecord(t *testing.T) {
+	cert := selfSigned(t, time.Now().Add(-90*time.Hour), time.Now().Add(10*time.Hour))
+	db := &fakeAccessor{record: certdb.CertificateRecord{Status: "revoked"}}
+
+	h := &Handler{Signer: fakeSigner{}, DB: db, RenewalWindow: 0.2}
+	err := h.Handle(httptest.NewRecorder(), renewalRequest(cert))
+	if err == nil {
+		t.Fatal("expected renewal of a revoked certificate to be refused")
+	}
+}
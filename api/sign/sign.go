@@ -0,0 +1,230 @@
+// Package sign implements the HTTP handler CFSSL serves sign requests
+// from, including the optional root CA bundle and PKCS#12 packaging
+// that remote.Signer.SignWithBundle and SignPKCS12 ask for.
+package sign
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/cloudflare/cfssl/api"
+	"github.com/cloudflare/cfssl/auth"
+	"github.com/cloudflare/cfssl/certdb"
+	cferr "github.com/cloudflare/cfssl/errors"
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/cloudflare/cfssl/signer"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+// Handler implements an api.HTTPRequestHandler for certificate sign
+// requests.
+type Handler struct {
+	Signer signer.Signer
+}
+
+// NewHandler returns an HTTP handler serving certificate signing
+// requests.
+func NewHandler(s signer.Signer) http.Handler {
+	return api.HTTPHandler{
+		Handler: &Handler{Signer: s},
+		Methods: []string{"POST"},
+	}
+}
+
+// Mount registers the sign endpoint, and its authenticated variant, on
+// mux at the standard CFSSL API paths.
+func Mount(mux *http.ServeMux, s signer.Signer) {
+	mux.Handle("/api/v1/cfssl/sign", NewHandler(s))
+	mux.Handle("/api/v1/cfssl/authsign", NewAuthHandler(s))
+}
+
+// Handle implements api.HTTPRequestHandler. On top of the plain
+// signed certificate, it honors ReturnBundle (adding root_ca_bundle,
+// read from the selected profile's RootBundlePath) and BundleFormat
+// "pkcs12" (adding a pkcs12 blob built from the signed certificate,
+// the chain, and the request's PrivateKey).
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+	return h.sign(w, body, nil)
+}
+
+// AuthHandler wraps a Handler, requiring that the selected profile's
+// RemoteProvider authenticate the request (as remote.Signer.remoteOp
+// presents it via api/client's AuthSign) before signing it. This is
+// the server-side counterpart of a profile's RemoteProvider: the same
+// field a client uses to attach a token is what a server configured
+// with AuthHandler uses to verify one.
+type AuthHandler struct {
+	*Handler
+}
+
+// NewAuthHandler returns an HTTP handler serving certificate signing
+// requests that must be authenticated against the selected profile's
+// RemoteProvider.
+func NewAuthHandler(s signer.Signer) http.Handler {
+	return api.HTTPHandler{
+		Handler: &AuthHandler{Handler: &Handler{Signer: s}},
+		Methods: []string{"POST"},
+	}
+}
+
+// Handle implements api.HTTPRequestHandler. It unwraps the
+// auth.AuthenticatedRequest envelope, verifies Token against Request
+// using the selected profile's RemoteProvider, and only then signs
+// Request exactly as Handler.Handle would. A profile with no
+// RemoteProvider configured refuses every request here, since there is
+// nothing to verify the token against.
+func (h *AuthHandler) Handle(w http.ResponseWriter, r *http.Request) error {
+	var authReq auth.AuthenticatedRequest
+	if err := json.NewDecoder(r.Body).Decode(&authReq); err != nil {
+		return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+
+	var req signer.SignRequest
+	if err := json.Unmarshal(authReq.Request, &req); err != nil {
+		return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+
+	profile, err := signer.Profile(h.Signer, req.Profile)
+	if err != nil {
+		return err
+	}
+	if profile.RemoteProvider == nil {
+		return cferr.New(cferr.AuthenticationFailure, cferr.ParseFailed)
+	}
+
+	ok, err := profile.RemoteProvider.Verify(authReq.Request, authReq.Token)
+	if err != nil || !ok {
+		return cferr.New(cferr.AuthenticationFailure, cferr.ParseFailed)
+	}
+
+	return h.sign(w, authReq.Request, &req)
+}
+
+// sign decodes body into a signer.SignRequest (unless req is already
+// decoded, in which case it's reused to avoid double-parsing) and
+// signs it, honoring ReturnBundle and BundleFormat exactly as Handle
+// documents, then persists and responds with the result. Both Handler
+// and AuthHandler funnel through here once authentication (if any) has
+// already been settled.
+func (h *Handler) sign(w http.ResponseWriter, body []byte, req *signer.SignRequest) error {
+	if req == nil {
+		req = &signer.SignRequest{}
+		if err := json.Unmarshal(body, req); err != nil {
+			return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+		}
+	}
+
+	certPEM, err := h.Signer.Sign(*req)
+	if err != nil {
+		return err
+	}
+
+	profile, err := signer.Profile(h.Signer, req.Profile)
+	if err != nil {
+		return err
+	}
+
+	result := map[string]string{"certificate": string(certPEM)}
+
+	var bundlePEM []byte
+	if (req.ReturnBundle || req.BundleFormat == "pkcs12") && profile.RootBundlePath != "" {
+		bundlePEM, err = os.ReadFile(profile.RootBundlePath)
+		if err != nil {
+			return cferr.Wrap(cferr.PolicyError, cferr.InvalidPolicy, err)
+		}
+	}
+
+	if req.ReturnBundle && len(bundlePEM) > 0 {
+		result["root_ca_bundle"] = string(bundlePEM)
+	}
+
+	if req.BundleFormat == "pkcs12" {
+		p12, err := packagePKCS12(certPEM, bundlePEM, []byte(req.PrivateKey), req.BundlePassword)
+		if err != nil {
+			return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+		}
+		result["pkcs12"] = base64.StdEncoding.EncodeToString(p12)
+	}
+
+	if err := h.dbPersist(*req, result["certificate"]); err != nil {
+		return err
+	}
+
+	return api.SendResponse(w, result)
+}
+
+// packagePKCS12 builds a password-protected PKCS#12 (PFX) blob from
+// the signed certificate, its private key, and the root/intermediate
+// chain, for consumers (Java keystores, Windows certificate stores,
+// browsers) that can't ingest PEM directly.
+func packagePKCS12(certPEM, bundlePEM, keyPEM []byte, password string) ([]byte, error) {
+	cert, err := helpers.ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := helpers.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	var caCerts []*x509.Certificate
+	if len(bundlePEM) > 0 {
+		caCerts, err = helpers.ParseCertificatesPEM(bundlePEM)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pkcs12.Encode(rand.Reader, key, cert, caCerts, password)
+}
+
+// dbPersist records the issued certificate, keyed the way api/renew
+// looks it up (Serial/AKI from the parsed cert), and its originating
+// request and profile, when the Signer has a certdb.Accessor
+// configured, so a later renewal can re-sign the same CSR without the
+// client resubmitting it. It is a no-op otherwise.
+func (h *Handler) dbPersist(req signer.SignRequest, certPEM string) error {
+	db := h.Signer.GetDBAccessor()
+	if db == nil {
+		return nil
+	}
+
+	// PrivateKey and BundlePassword are supplied only so this handler
+	// can fold them into a PKCS#12 bundle (see SignRequest's doc
+	// comments); strip them before the request is written to certdb.
+	sanitized := req
+	sanitized.PrivateKey = ""
+	sanitized.BundlePassword = ""
+	reqJSON, err := json.Marshal(sanitized)
+	if err != nil {
+		return cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+
+	record := certdb.CertificateRecord{
+		CAProfile: req.Profile,
+		CALabel:   req.Label,
+		Status:    "good",
+		Request:   string(reqJSON),
+		PEM:       certPEM,
+	}
+
+	if cert, err := helpers.ParseCertificatePEM([]byte(certPEM)); err == nil {
+		record.Serial = cert.SerialNumber.String()
+		record.Subject = cert.Subject.String()
+		record.AKI = hex.EncodeToString(cert.AuthorityKeyId)
+		record.Expiry = cert.NotAfter
+	}
+
+	return db.InsertCertificate(record)
+}
@@ -0,0 +1,94 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cfssl/config"
+	"github.com/cloudflare/cfssl/signer"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+func selfSignedKeyPairPEM(t *testing.T, cn string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+func TestPackagePKCS12RoundTrips(t *testing.T) {
+	certPEM, keyPEM := selfSignedKeyPairPEM(t, "leaf")
+
+	p12, err := packagePKCS12(certPEM, nil, keyPEM, "s3cr3t")
+	if err != nil {
+		t.Fatalf("packagePKCS12: %s", err)
+	}
+
+	gotKey, gotCert, err := pkcs12.Decode(p12, "s3cr3t")
+	if err != nil {
+		t.Fatalf("decoding produced PKCS#12 blob: %s", err)
+	}
+	if gotCert == nil {
+		t.Fatal("expected the decoded PKCS#12 blob to contain the leaf certificate")
+	}
+	if gotKey == nil {
+		t.Fatal("expected the decoded PKCS#12 blob to contain the private key")
+	}
+
+	if _, err := pkcs12.Decode(p12, "wrong-password"); err == nil {
+		t.Error("expected decoding with the wrong password to fail")
+	}
+}
+
+func TestHandlePackagesPKCS12WhenRequested(t *testing.T) {
+	certPEM, keyPEM := selfSignedKeyPairPEM(t, "leaf")
+
+	s := &fakeSigner{certPEM: certPEM}
+	s.SetPolicy(&config.Signing{Default: &config.SigningProfile{Usage: []string{"signing"}}})
+
+	result := doSign(t, s, signer.SignRequest{
+		BundleFormat:   "pkcs12",
+		BundlePassword: "s3cr3t",
+		PrivateKey:     string(keyPEM),
+	})
+
+	p12B64, ok := result["pkcs12"].(string)
+	if !ok || p12B64 == "" {
+		t.Fatalf("expected a non-empty base64 pkcs12 field in the response, got %v", result["pkcs12"])
+	}
+
+	p12, err := base64.StdEncoding.DecodeString(p12B64)
+	if err != nil {
+		t.Fatalf("decoding base64 pkcs12: %s", err)
+	}
+	if _, _, err := pkcs12.Decode(p12, "s3cr3t"); err != nil {
+		t.Errorf("decoding returned pkcs12 blob: %s", err)
+	}
+}
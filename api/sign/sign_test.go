@@ -0,0 +1,137 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cfssl/certdb"
+	"github.com/cloudflare/cfssl/config"
+	"github.com/cloudflare/cfssl/info"
+	"github.com/cloudflare/cfssl/signer"
+)
+
+// selfSignedPEM returns a freshly generated self-signed certificate,
+// PEM-encoded, standing in for whatever a real Signer would issue.
+func selfSignedPEM(t *testing.T, cn string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// fakeSigner always returns a fixed certificate, and reports the
+// policy last given to SetPolicy via Policy/Profile lookup.
+type fakeSigner struct {
+	certPEM []byte
+	policy  *config.Signing
+	db      certdb.Accessor
+}
+
+func (f *fakeSigner) Info(info.Req) (*info.Resp, error)          { return nil, nil }
+func (f *fakeSigner) Sign(signer.SignRequest) ([]byte, error)    { return f.certPEM, nil }
+func (f *fakeSigner) SigAlgo() x509.SignatureAlgorithm           { return x509.ECDSAWithSHA256 }
+func (f *fakeSigner) SetPolicy(p *config.Signing)                { f.policy = p }
+func (f *fakeSigner) SetDBAccessor(db certdb.Accessor)           { f.db = db }
+func (f *fakeSigner) GetDBAccessor() certdb.Accessor             { return f.db }
+func (f *fakeSigner) Policy() *config.Signing                    { return f.policy }
+func (f *fakeSigner) SetReqModifier(func(*http.Request, []byte)) {}
+
+func doSign(t *testing.T, s signer.Signer, req signer.SignRequest) map[string]interface{} {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(s)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/v1/cfssl/sign", strings.NewReader(string(body)))
+	h.ServeHTTP(w, r)
+
+	var envelope struct {
+		Success bool                     `json:"success"`
+		Result  map[string]interface{}   `json:"result"`
+		Errors  []map[string]interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decoding response: %s (body: %s)", err, w.Body.String())
+	}
+	if !envelope.Success {
+		t.Fatalf("expected a successful response, got errors: %v", envelope.Errors)
+	}
+	return envelope.Result
+}
+
+func TestHandleReturnsBundleWhenRequestedAndConfigured(t *testing.T) {
+	bundlePEM := selfSignedPEM(t, "root")
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "bundle.pem")
+	if err := os.WriteFile(bundlePath, bundlePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeSigner{certPEM: selfSignedPEM(t, "leaf")}
+	s.SetPolicy(&config.Signing{Default: &config.SigningProfile{Usage: []string{"signing"}, RootBundlePath: bundlePath}})
+
+	result := doSign(t, s, signer.SignRequest{ReturnBundle: true})
+
+	if result["root_ca_bundle"] != string(bundlePEM) {
+		t.Errorf("expected root_ca_bundle to equal the configured bundle file contents")
+	}
+}
+
+func TestHandleOmitsBundleWhenNotRequested(t *testing.T) {
+	bundlePEM := selfSignedPEM(t, "root")
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "bundle.pem")
+	if err := os.WriteFile(bundlePath, bundlePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeSigner{certPEM: selfSignedPEM(t, "leaf")}
+	s.SetPolicy(&config.Signing{Default: &config.SigningProfile{Usage: []string{"signing"}, RootBundlePath: bundlePath}})
+
+	result := doSign(t, s, signer.SignRequest{})
+
+	if _, ok := result["root_ca_bundle"]; ok {
+		t.Errorf("expected root_ca_bundle to be omitted when ReturnBundle is false, got %v", result["root_ca_bundle"])
+	}
+}
+
+func TestHandleOmitsBundleWhenNoneConfigured(t *testing.T) {
+	s := &fakeSigner{certPEM: selfSignedPEM(t, "leaf")}
+	s.SetPolicy(&config.Signing{Default: &config.SigningProfile{Usage: []string{"signing"}}})
+
+	result := doSign(t, s, signer.SignRequest{ReturnBundle: true})
+
+	if _, ok := result["root_ca_bundle"]; ok {
+		t.Errorf("expected root_ca_bundle to be omitted when no RootBundlePath is configured, got %v", result["root_ca_bundle"])
+	}
+}
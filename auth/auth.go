@@ -0,0 +1,27 @@
+// Package auth defines the interface a sign-request authentication
+// scheme implements, and is consumed by api/sign's AuthHandler on the
+// server side and remote.Signer.remoteOp (through api/client's
+// AuthSign) on the client side.
+package auth
+
+// Provider issues and verifies the authentication token attached to a
+// sign request body.
+type Provider interface {
+	// Token produces the token to attach to request.
+	Token(request []byte) (token []byte, err error)
+
+	// Verify reports whether token authenticates request.
+	Verify(request, token []byte) (bool, error)
+}
+
+// AuthenticatedRequest is the wire envelope api/client's AuthSign posts
+// in place of a bare SignRequest body: Request is the JSON-encoded
+// signer.SignRequest, and Token is whatever the client's Provider
+// produced by calling Token(Request). A server endpoint that requires
+// authentication decodes this envelope, unmarshals Request to find the
+// selected profile, and calls that profile's Provider.Verify(Request,
+// Token) before signing it.
+type AuthenticatedRequest struct {
+	Token   []byte `json:"token"`
+	Request []byte `json:"request"`
+}
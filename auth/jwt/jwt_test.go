@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestClaimsPolicyAllows(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy ClaimsPolicy
+		claims claims
+		want   bool
+	}{
+		{
+			name:   "unrestricted policy allows anything",
+			policy: ClaimsPolicy{},
+			claims: claims{KeyType: "rsa", Profile: "server", SANs: []string{"example.com"}, Lifetime: 86400},
+			want:   true,
+		},
+		{
+			name:   "lifetime within max is allowed",
+			policy: ClaimsPolicy{MaxLifetime: 24 * time.Hour},
+			claims: claims{Lifetime: 3600},
+			want:   true,
+		},
+		{
+			name:   "lifetime over max is refused",
+			policy: ClaimsPolicy{MaxLifetime: time.Hour},
+			claims: claims{Lifetime: 7200},
+			want:   false,
+		},
+		{
+			name:   "disallowed key type is refused",
+			policy: ClaimsPolicy{AllowedKeyTypes: []string{"ecdsa"}},
+			claims: claims{KeyType: "rsa"},
+			want:   false,
+		},
+		{
+			name:   "allowed key type is allowed",
+			policy: ClaimsPolicy{AllowedKeyTypes: []string{"ecdsa", "rsa"}},
+			claims: claims{KeyType: "rsa"},
+			want:   true,
+		},
+		{
+			name:   "disallowed profile is refused",
+			policy: ClaimsPolicy{AllowedProfiles: []string{"server"}},
+			claims: claims{Profile: "client"},
+			want:   false,
+		},
+		{
+			name:   "exact SAN match is allowed",
+			policy: ClaimsPolicy{AllowedSANs: []string{"api.example.com"}},
+			claims: claims{SANs: []string{"api.example.com"}},
+			want:   true,
+		},
+		{
+			name:   "wildcard SAN match is allowed",
+			policy: ClaimsPolicy{AllowedSANs: []string{"*.example.com"}},
+			claims: claims{SANs: []string{"api.example.com"}},
+			want:   true,
+		},
+		{
+			name:   "SAN outside the allowed set is refused",
+			policy: ClaimsPolicy{AllowedSANs: []string{"*.example.com"}},
+			claims: claims{SANs: []string{"api.example.com", "evil.com"}},
+			want:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.allows(tc.claims); got != tc.want {
+				t.Errorf("allows() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewProviderIndexesByKid(t *testing.T) {
+	a := &Provisioner{Name: "team-a", JWK: JWK{Kid: "key-a"}}
+	b := &Provisioner{Name: "team-b", JWK: JWK{Kid: "key-b"}}
+
+	p := NewProvider([]*Provisioner{a, b})
+
+	if p.provisioners["key-a"] != a {
+		t.Errorf("expected provisioner %q to be indexed under kid %q", a.Name, a.JWK.Kid)
+	}
+	if p.provisioners["key-b"] != b {
+		t.Errorf("expected provisioner %q to be indexed under kid %q", b.Name, b.JWK.Kid)
+	}
+	if len(p.provisioners) != 2 {
+		t.Errorf("expected 2 indexed provisioners, got %d", len(p.provisioners))
+	}
+}
+
+func TestProviderVerifyRejectsUnknownKid(t *testing.T) {
+	p := NewProvider([]*Provisioner{{Name: "team-a", JWK: JWK{Kid: "key-a"}}})
+
+	// A well-formed but non-JWT token (wrong number of segments) and a
+	// token naming an unregistered kid should both be rejected without
+	// panicking.
+	if ok, err := p.Verify([]byte("request"), []byte("not-a-jwt")); ok || err == nil {
+		t.Errorf("expected malformed token to be rejected, got ok=%v err=%v", ok, err)
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"key-unknown"}`))
+	unknownKid := header + ".e30.c2ln"
+	if ok, err := p.Verify([]byte("request"), []byte(unknownKid)); ok || err == nil {
+		t.Errorf("expected token with an unregistered kid to be rejected, got ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package renew runs certificate renewal on a timer in the background,
+// so long-running services can hold a self-renewing mTLS identity
+// without operator intervention. It pairs with the remote renewal flow
+// implemented by signer/remote.Signer.Renew and api/renew.
+package renew
+
+import (
+	"crypto"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/cloudflare/cfssl/log"
+	"github.com/cloudflare/cfssl/signer/remote"
+)
+
+// Renewer keeps a certificate fresh in the background, renewing it
+// through a remote.Signer well before it expires and swapping it in
+// atomically so in-flight TLS connections are unaffected. Its
+// GetCertificate method can be plugged directly into a tls.Config.
+type Renewer struct {
+	signer *remote.Signer
+	key    crypto.Signer
+
+	// Before is how long before expiry a renewal is attempted.
+	Before time.Duration
+
+	// CheckInterval is how often the renewal condition is evaluated.
+	CheckInterval time.Duration
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// New creates a Renewer holding certPEM/key, renewing through s. The
+// certificate is parsed immediately so GetCertificate is usable right
+// away; a background goroutine then renews it every checkInterval once
+// it is within before of expiring.
+func New(s *remote.Signer, certPEM []byte, key crypto.Signer, before, checkInterval time.Duration) (*Renewer, error) {
+	leaf, err := helpers.ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renewer{
+		signer:        s,
+		key:           key,
+		Before:        before,
+		CheckInterval: checkInterval,
+		cert: &tls.Certificate{
+			Certificate: [][]byte{leaf.Raw},
+			PrivateKey:  key,
+			Leaf:        leaf,
+		},
+	}
+
+	go r.loop()
+	return r, nil
+}
+
+func (r *Renewer) loop() {
+	ticker := time.NewTicker(r.CheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.maybeRenew()
+	}
+}
+
+func (r *Renewer) maybeRenew() {
+	r.mu.RLock()
+	leaf := r.cert.Leaf
+	r.mu.RUnlock()
+
+	if time.Until(leaf.NotAfter) > r.Before {
+		return
+	}
+
+	renewed, err := r.signer.Renew(leaf, r.key)
+	if err != nil {
+		log.Errorf("certificate renewal failed, will retry: %s", err)
+		return
+	}
+
+	newLeaf, err := helpers.ParseCertificatePEM(renewed)
+	if err != nil {
+		log.Errorf("failed to parse renewed certificate: %s", err)
+		return
+	}
+
+	r.mu.Lock()
+	r.cert = &tls.Certificate{
+		Certificate: [][]byte{newLeaf.Raw},
+		PrivateKey:  r.key,
+		Leaf:        newLeaf,
+	}
+	r.mu.Unlock()
+
+	log.Infof("renewed certificate, new expiry %s", newLeaf.NotAfter)
+}
+
+// GetCertificate implements the signature tls.Config.GetCertificate
+// expects, so a Renewer can keep a server or client's identity current
+// without restarting it.
+func (r *Renewer) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
@@ -0,0 +1,35 @@
+// Package certdb defines the interface CFSSL uses to persist and query
+// issued certificate records.
+package certdb
+
+import "time"
+
+// CertificateRecord is a single row describing a certificate CFSSL has
+// issued or revoked.
+type CertificateRecord struct {
+	Serial    string
+	Subject   string
+	AKI       string
+	CALabel   string
+	CAProfile string
+	Status    string
+	Reason    int
+	Expiry    time.Time
+	RevokedAt time.Time
+	PEM       string
+
+	// Request is the JSON-encoded signer.SignRequest the
+	// certificate was originally issued from, so a later renewal
+	// (see api/renew) can re-sign the same CSR under the same
+	// profile without the client needing to resubmit it.
+	Request string
+}
+
+// Accessor is the interface a certdb backend (SQL, in the future
+// others) must implement.
+type Accessor interface {
+	InsertCertificate(cr CertificateRecord) error
+	GetCertificate(serial, aki string) ([]CertificateRecord, error)
+	GetUnexpiredCertificates() ([]CertificateRecord, error)
+	RevokeCertificate(serial, aki string, reasonCode int) error
+}
@@ -0,0 +1,84 @@
+// Package cli implements the shared flag parsing and dispatch for
+// CFSSL's subcommands.
+package cli
+
+import (
+	"flag"
+	"time"
+)
+
+// Config collects every flag value any Command might need; a given
+// Command only sees the fields it opted into via its Flags list, but
+// they're kept on one struct so handlers can be passed around as a
+// single value.
+type Config struct {
+	Address      string
+	Port         int
+	Path         string
+	Responses    string
+	DBConfigFile string
+	Redis        string
+
+	// OCSP caching (see cli/ocspserve -responder-cert/-responder-key).
+	ResponderCert    string
+	ResponderKey     string
+	ResponseValidity time.Duration
+	RefreshInterval  time.Duration
+}
+
+// Command is a CFSSL subcommand: UsageText/Flags describe it to the
+// top-level dispatcher, and Main implements it once Config has been
+// populated from the flags it declared.
+type Command struct {
+	UsageText string
+	Flags     []string
+	Main      func(args []string, c Config) error
+}
+
+// flagRegistry associates each flag name a Command can declare (in its
+// Flags field) with the code that registers it on a FlagSet and the
+// Config field it populates.
+var flagRegistry = map[string]func(fs *flag.FlagSet, c *Config){
+	"address": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.Address, "address", "127.0.0.1", "Address to bind")
+	},
+	"port": func(fs *flag.FlagSet, c *Config) {
+		fs.IntVar(&c.Port, "port", 8888, "Port to bind")
+	},
+	"path": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.Path, "path", "", "Path to bind the HTTP handler on")
+	},
+	"responses": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.Responses, "responses", "", "Precomputed OCSP responses file")
+	},
+	"db-config": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.DBConfigFile, "db-config", "", "certdb configuration file")
+	},
+	"redis": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.Redis, "redis", "", "Redis host:port")
+	},
+	"refresh-interval": func(fs *flag.FlagSet, c *Config) {
+		fs.DurationVar(&c.RefreshInterval, "refresh-interval", 0, "How often to re-sign the OCSP response cache (default: half of -response-validity)")
+	},
+	"response-validity": func(fs *flag.FlagSet, c *Config) {
+		fs.DurationVar(&c.ResponseValidity, "response-validity", 4*time.Hour, "Validity period given to each pre-signed OCSP response")
+	},
+	"responder-cert": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.ResponderCert, "responder-cert", "", "Certificate used to sign pre-computed OCSP responses")
+	},
+	"responder-key": func(fs *flag.FlagSet, c *Config) {
+		fs.StringVar(&c.ResponderKey, "responder-key", "", "Private key used to sign pre-computed OCSP responses")
+	},
+}
+
+// RegisterFlags binds the flags a Command declared onto fs and returns
+// the Config they populate.
+func RegisterFlags(fs *flag.FlagSet, cmd *Command) *Config {
+	c := &Config{}
+	for _, name := range cmd.Flags {
+		if register, ok := flagRegistry[name]; ok {
+			register(fs, c)
+		}
+	}
+	return c
+}
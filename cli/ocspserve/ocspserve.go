@@ -5,9 +5,14 @@ import (
 	"errors"
 	"net"
 	"net/http"
+	"os"
 	"strconv"
+	"time"
 
+	"github.com/cloudflare/cfssl/certdb/dbconf"
+	certsql "github.com/cloudflare/cfssl/certdb/sql"
 	"github.com/cloudflare/cfssl/cli"
+	"github.com/cloudflare/cfssl/helpers"
 	"github.com/cloudflare/cfssl/log"
 	"github.com/cloudflare/cfssl/ocsp"
 )
@@ -22,7 +27,8 @@ var ocspServerUsageText = `cfssl ocspserve -- set up an HTTP server that handles
   `
 
 // Flags used by 'cfssl serve'
-var ocspServerFlags = []string{"address", "port", "responses", "db-config", "redis"}
+var ocspServerFlags = []string{"address", "port", "responses", "db-config", "redis",
+	"refresh-interval", "response-validity", "responder-cert", "responder-key"}
 
 // ocspServerMain is the command line entry point to the OCSP responder.
 // It sets up a new HTTP server that responds to OCSP requests.
@@ -51,6 +57,54 @@ func ocspServerMain(args []string, c cli.Config) error {
 			return errors.New("unable to read configuration file")
 		}
 		src = s
+
+		// A database-backed source can optionally run a background
+		// worker that pre-signs responses for every unexpired serial,
+		// decoupling request latency (and CA key access) from serving
+		// OCSP queries.
+		if c.ResponderCert != "" && c.ResponderKey != "" {
+			certPEM, err := os.ReadFile(c.ResponderCert)
+			if err != nil {
+				return errors.New("unable to read OCSP responder certificate")
+			}
+			responderCert, err := helpers.ParseCertificatePEM(certPEM)
+			if err != nil {
+				return errors.New("unable to parse OCSP responder certificate")
+			}
+
+			keyPEM, err := os.ReadFile(c.ResponderKey)
+			if err != nil {
+				return errors.New("unable to read OCSP responder key")
+			}
+			responderKey, err := helpers.ParsePrivateKeyPEM(keyPEM)
+			if err != nil {
+				return errors.New("unable to parse OCSP responder key")
+			}
+
+			sqlDB, err := dbconf.DBFromConfig(c.DBConfigFile)
+			if err != nil {
+				return errors.New("unable to read configuration file")
+			}
+			db := certsql.NewAccessor(sqlDB)
+
+			responseValidity := c.ResponseValidity
+			if responseValidity == 0 {
+				responseValidity = 4 * time.Hour
+			}
+			refreshInterval := c.RefreshInterval
+			if refreshInterval == 0 {
+				refreshInterval = responseValidity / 2
+			}
+
+			src = ocsp.NewCachingSource(src, ocsp.RefresherConfig{
+				DB:               db,
+				Issuer:           responderCert,
+				ResponderCert:    responderCert,
+				ResponderKey:     responderKey,
+				ResponseValidity: responseValidity,
+				RefreshInterval:  refreshInterval,
+			})
+		}
 	} else {
 		return errors.New(
 			"no response file nor db/redis config provided, please set the one of these using either -responses or -db-config or -redis flags",
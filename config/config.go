@@ -0,0 +1,91 @@
+// Package config defines the signing policy used by both local and
+// remote signers.
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/cloudflare/cfssl/auth"
+)
+
+// SigningProfile stores information used to sign certificates under
+// one named profile of a Signing policy.
+type SigningProfile struct {
+	Usage        []string      `json:"usage"`
+	Expiry       time.Duration `json:"expiry"`
+	ExpiryString string        `json:"expiry_string,omitempty"`
+
+	// RemoteServer, RemoteCAs, ClientCert, and RemoteProvider
+	// configure this profile as a delegate to an upstream remote
+	// CFSSL: RemoteServer is where the request is forwarded,
+	// RemoteCAs authenticates that server, ClientCert authenticates
+	// us to it at the TLS layer, and RemoteProvider — when set —
+	// additionally authenticates the forwarded sign request (see
+	// remote.Signer.remoteOp).
+	RemoteServer   string           `json:"remote"`
+	RemoteCAs      *x509.CertPool   `json:"-"`
+	ClientCert     *tls.Certificate `json:"-"`
+	RemoteProvider auth.Provider    `json:"-"`
+
+	// RootBundlePath is the PEM root CA bundle (and any cross-signed
+	// intermediates) handed back to callers that set
+	// SignRequest.ReturnBundle, and bundled into PKCS#12 output.
+	RootBundlePath string `json:"-"`
+}
+
+// Signing groups the set of named SigningProfiles a CA enforces.
+type Signing struct {
+	Profiles map[string]*SigningProfile `json:"profiles"`
+	Default  *SigningProfile            `json:"default"`
+}
+
+// validProfile checks that the profile has a sane configuration: a
+// profile that names a RemoteServer is valid as is, and any other
+// profile must list at least one usage.
+func (p *SigningProfile) validProfile() bool {
+	if p == nil {
+		return false
+	}
+	if p.RemoteServer != "" {
+		return true
+	}
+	return len(p.Usage) > 0
+}
+
+// Valid checks that the policy has a usable Default profile and that
+// every named profile is itself valid.
+func (s *Signing) Valid() bool {
+	if s == nil || s.Default == nil || !s.Default.validProfile() {
+		return false
+	}
+	for _, p := range s.Profiles {
+		if !p.validProfile() {
+			return false
+		}
+	}
+	return true
+}
+
+// Config is the top-level CFSSL configuration file format.
+type Config struct {
+	Signing *Signing `json:"signing"`
+}
+
+// LoadFile reads a JSON CFSSL configuration file from path.
+func LoadFile(path string) (*Config, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(body, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -13,9 +13,11 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"encoding/json"
 	"encoding/pem"
 	"io/ioutil"
 
+	"github.com/cloudflare/cfssl/auth/jwt"
 	"github.com/cloudflare/cfssl/certdb/dbconf"
 	"github.com/cloudflare/cfssl/config"
 	"github.com/cloudflare/cfssl/helpers"
@@ -107,6 +109,17 @@ type Root struct {
 	Config      *config.Signing
 	ACL         whitelist.NetACL
 	DB          *sqlx.DB
+
+	// BundlePath is the PEM file of the root CA bundle (and any
+	// cross-signed intermediates) to hand back to callers that set
+	// SignRequest.ReturnBundle, so clients always trust whatever
+	// bundle the CA currently advertises.
+	BundlePath string
+
+	// Provisioners are the JWT/JWK identities, loaded from the
+	// "provisioners" config key, allowed to authorize sign requests
+	// for this root without sharing a single HMAC secret.
+	Provisioners []*jwt.Provisioner
 }
 
 // LoadRoot parses a config structure into a Root structure
@@ -157,6 +170,26 @@ func LoadRoot(cfg map[string]string) (*Root, error) {
 		}
 	}
 
+	root.BundlePath = cfg["bundle"]
+	if root.BundlePath != "" {
+		setProfiles(root.Config, func(p *config.SigningProfile) {
+			p.RootBundlePath = root.BundlePath
+		})
+	}
+
+	provisioners := cfg["provisioners"]
+	if provisioners != "" {
+		root.Provisioners, err = loadProvisioners(provisioners)
+		if err != nil {
+			return nil, err
+		}
+
+		provider := jwt.NewProvider(root.Provisioners)
+		setProfiles(root.Config, func(p *config.SigningProfile) {
+			p.RemoteProvider = provider
+		})
+	}
+
 	dbConfig := cfg["dbconfig"]
 	if dbConfig != "" {
 		db, err := dbconf.DBFromConfig(dbConfig)
@@ -169,6 +202,40 @@ func LoadRoot(cfg map[string]string) (*Root, error) {
 	return &root, nil
 }
 
+// setProfiles applies fn to every SigningProfile in s: its Default and
+// each named profile, so a Root-level setting (the bundle path, the
+// JWT provider) reaches every profile a sign request might select.
+func setProfiles(s *config.Signing, fn func(*config.SigningProfile)) {
+	if s == nil {
+		return
+	}
+	if s.Default != nil {
+		fn(s.Default)
+	}
+	for _, p := range s.Profiles {
+		if p != nil {
+			fn(p)
+		}
+	}
+}
+
+// loadProvisioners reads a JSON file of provisioners, each granting a
+// JWK-holding identity the right to authorize sign requests under the
+// claims policy it was configured with (see auth/jwt).
+func loadProvisioners(path string) ([]*jwt.Provisioner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var provisioners []*jwt.Provisioner
+	if err := json.Unmarshal(data, &provisioners); err != nil {
+		return nil, err
+	}
+
+	return provisioners, nil
+}
+
 func parsePrivateKeySpec(spec string, cfg map[string]string) (crypto.Signer, error) {
 	specURL, err := url.Parse(spec)
 	if err != nil {
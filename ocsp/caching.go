@@ -0,0 +1,161 @@
+package ocsp
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cfssl/certdb"
+	"github.com/cloudflare/cfssl/log"
+	xocsp "golang.org/x/crypto/ocsp"
+)
+
+// RefresherConfig configures the background pre-signing worker run by
+// a CachingSource.
+type RefresherConfig struct {
+	// DB is walked for the set of certificates to keep responses
+	// cached for.
+	DB certdb.Accessor
+
+	// Issuer is the CA certificate that issued the certificates
+	// being covered; it populates the response's issuer hash
+	// fields.
+	Issuer *x509.Certificate
+
+	// ResponderCert and ResponderKey sign each pre-generated
+	// response.
+	ResponderCert *x509.Certificate
+	ResponderKey  crypto.Signer
+
+	// ResponseValidity is how long each signed response is valid
+	// for; NextUpdate is set to half of this.
+	ResponseValidity time.Duration
+
+	// RefreshInterval is how often the worker walks certdb and
+	// re-signs the response for every unexpired certificate.
+	RefreshInterval time.Duration
+}
+
+// CachingSource wraps an existing Source, serving pre-signed responses
+// on hit and falling back to the wrapped Source (caching the result)
+// on miss. A background worker walks certdb on RefreshInterval and
+// keeps the cache populated using ResponderKey, so a query for a
+// cached serial needs neither the CA key nor certdb online — the same
+// way large CAs run OCSP at CDN edges. A miss is answered by the
+// wrapped Source.
+type CachingSource struct {
+	Source
+	cfg RefresherConfig
+
+	mu    sync.RWMutex
+	cache map[string][]byte
+}
+
+// NewCachingSource wraps src with an in-memory response cache and
+// starts the background refresh worker described by cfg.
+func NewCachingSource(src Source, cfg RefresherConfig) *CachingSource {
+	cs := &CachingSource{
+		Source: src,
+		cfg:    cfg,
+		cache:  make(map[string][]byte),
+	}
+	go cs.refreshLoop()
+	return cs
+}
+
+func cacheKey(serial, aki string) string {
+	return serial + "||" + aki
+}
+
+// Response returns a cached, pre-signed response when one is
+// available; otherwise it returns whatever the wrapped Source answers
+// and caches that for subsequent requests. A miss does not sign a
+// response with ResponderKey.
+func (cs *CachingSource) Response(req *xocsp.Request) ([]byte, http.Header, error) {
+	key := cacheKey(req.SerialNumber.String(), hex.EncodeToString(req.IssuerKeyHash))
+
+	cs.mu.RLock()
+	cached, ok := cs.cache[key]
+	cs.mu.RUnlock()
+	if ok {
+		return cached, nil, nil
+	}
+
+	resp, header, err := cs.Source.Response(req)
+	if err != nil {
+		return nil, header, err
+	}
+
+	cs.mu.Lock()
+	cs.cache[key] = resp
+	cs.mu.Unlock()
+
+	return resp, header, nil
+}
+
+func (cs *CachingSource) refreshLoop() {
+	if cs.cfg.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cs.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		cs.refreshAll()
+		<-ticker.C
+	}
+}
+
+func (cs *CachingSource) refreshAll() {
+	if cs.cfg.DB == nil {
+		return
+	}
+	records, err := cs.cfg.DB.GetUnexpiredCertificates()
+	if err != nil {
+		log.Errorf("ocsp: failed to load certificates for refresh: %s", err)
+		return
+	}
+
+	for _, record := range records {
+		if err := cs.signAndCache(record); err != nil {
+			log.Errorf("ocsp: failed to pre-sign response for serial %s: %s", record.Serial, err)
+		}
+	}
+}
+
+func (cs *CachingSource) signAndCache(record certdb.CertificateRecord) error {
+	serialNumber, ok := new(big.Int).SetString(record.Serial, 10)
+	if !ok {
+		return errors.New("ocsp: invalid serial number in certdb record")
+	}
+
+	status := xocsp.Good
+	var revokedAt time.Time
+	if record.Status == "revoked" {
+		status = xocsp.Revoked
+		revokedAt = record.RevokedAt
+	}
+
+	template := xocsp.Response{
+		Status:       status,
+		SerialNumber: serialNumber,
+		IssuerHash:   crypto.SHA1,
+		RevokedAt:    revokedAt,
+		ThisUpdate:   time.Now(),
+		NextUpdate:   time.Now().Add(cs.cfg.ResponseValidity / 2),
+	}
+
+	resp, err := xocsp.CreateResponse(cs.cfg.Issuer, cs.cfg.ResponderCert, template, cs.cfg.ResponderKey)
+	if err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	cs.cache[cacheKey(record.Serial, record.AKI)] = resp
+	cs.mu.Unlock()
+	return nil
+}
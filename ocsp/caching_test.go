@@ -0,0 +1,144 @@
+package ocsp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cfssl/certdb"
+	xocsp "golang.org/x/crypto/ocsp"
+)
+
+// stubSource always returns a fixed response, recording how many times
+// it was asked, so tests can tell a cache hit (no call) from a miss.
+type stubSource struct {
+	calls int
+	resp  []byte
+}
+
+func (s *stubSource) Response(req *xocsp.Request) ([]byte, http.Header, error) {
+	s.calls++
+	return s.resp, nil, nil
+}
+
+func TestCachingSourceServesFromCacheOnSecondCall(t *testing.T) {
+	stub := &stubSource{resp: []byte("der-response")}
+	cs := &CachingSource{Source: stub, cache: make(map[string][]byte)}
+
+	req := &xocsp.Request{SerialNumber: big.NewInt(42), IssuerKeyHash: []byte{1, 2, 3}}
+
+	resp1, _, err := cs.Response(req)
+	if err != nil {
+		t.Fatalf("first call: unexpected error: %s", err)
+	}
+	if string(resp1) != "der-response" {
+		t.Fatalf("first call: got %q, want der-response", resp1)
+	}
+	if stub.calls != 1 {
+		t.Fatalf("first call: expected underlying Source to be called once, got %d", stub.calls)
+	}
+
+	resp2, _, err := cs.Response(req)
+	if err != nil {
+		t.Fatalf("second call: unexpected error: %s", err)
+	}
+	if string(resp2) != "der-response" {
+		t.Fatalf("second call: got %q, want der-response", resp2)
+	}
+	if stub.calls != 1 {
+		t.Fatalf("second call: expected a cache hit (no further calls to Source), got %d total calls", stub.calls)
+	}
+}
+
+func TestCachingSourceMissesOnDifferentKey(t *testing.T) {
+	stub := &stubSource{resp: []byte("der-response")}
+	cs := &CachingSource{Source: stub, cache: make(map[string][]byte)}
+
+	if _, _, err := cs.Response(&xocsp.Request{SerialNumber: big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cs.Response(&xocsp.Request{SerialNumber: big.NewInt(2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stub.calls != 2 {
+		t.Errorf("expected a distinct serial number to miss the cache, got %d calls for 2 distinct requests", stub.calls)
+	}
+}
+
+func TestSignAndCacheProducesGoodAndRevokedResponses(t *testing.T) {
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(99),
+		Subject:               pkix.Name{CommonName: "issuer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	issuerDER, err := x509.CreateCertificate(rand.Reader, issuerTemplate, issuerTemplate, &issuerKey.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := x509.ParseCertificate(issuerDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := &CachingSource{cache: make(map[string][]byte), cfg: RefresherConfig{
+		Issuer:           issuer,
+		ResponderCert:    issuer,
+		ResponderKey:     issuerKey,
+		ResponseValidity: time.Hour,
+	}}
+
+	cases := []struct {
+		name       string
+		status     string
+		wantStatus int
+	}{
+		{"good certificate", "good", xocsp.Good},
+		{"revoked certificate", "revoked", xocsp.Revoked},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := certdb.CertificateRecord{Serial: "123", AKI: "abcd", Status: tc.status}
+			if err := cs.signAndCache(record); err != nil {
+				t.Fatalf("signAndCache: %s", err)
+			}
+
+			key := cacheKey("123", "abcd")
+			der, ok := cs.cache[key]
+			if !ok {
+				t.Fatal("expected a cached response to be populated")
+			}
+
+			parsed, err := xocsp.ParseResponse(der, issuer)
+			if err != nil {
+				t.Fatalf("parsing signed response: %s", err)
+			}
+			if parsed.Status != tc.wantStatus {
+				t.Errorf("got status %d, want %d", parsed.Status, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCacheKeyDistinguishesSerialAndAKI(t *testing.T) {
+	a := cacheKey("1", hex.EncodeToString([]byte{0xaa}))
+	b := cacheKey("1", hex.EncodeToString([]byte{0xbb}))
+	if a == b {
+		t.Error("expected different AKIs to produce different cache keys for the same serial")
+	}
+}
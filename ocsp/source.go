@@ -0,0 +1,14 @@
+package ocsp
+
+import (
+	"net/http"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// Source answers an OCSP request with the DER-encoded response for the
+// certificate it identifies, plus any extra headers (e.g.
+// Cache-Control) the responder should set on the HTTP response.
+type Source interface {
+	Response(*ocsp.Request) ([]byte, http.Header, error)
+}
@@ -1,12 +1,20 @@
 package remote
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/cloudflare/cfssl/api"
 	"github.com/cloudflare/cfssl/api/client"
 	"github.com/cloudflare/cfssl/certdb"
 	"github.com/cloudflare/cfssl/config"
@@ -14,6 +22,7 @@ import (
 	"github.com/cloudflare/cfssl/helpers"
 	"github.com/cloudflare/cfssl/info"
 	"github.com/cloudflare/cfssl/log"
+	"github.com/cloudflare/cfssl/revoke"
 	"github.com/cloudflare/cfssl/signer"
 )
 
@@ -23,6 +32,31 @@ type Signer struct {
 	policy      *config.Signing
 	reqModifier func(*http.Request, []byte)
 	dbAccessor  certdb.Accessor
+	verify      *VerifyOptions
+	revokeCache revocationCache
+}
+
+// VerifyOptions configures the revocation-aware chain validation
+// performed by Sign once SetVerifyOptions has been called. Without it,
+// Sign trusts whatever certificate the remote server returns, which
+// leaves no way to notice a compromised intermediate minting
+// valid-looking leaves through a remote CFSSL.
+type VerifyOptions struct {
+	// Roots and Intermediates are used to build the chain from the
+	// returned leaf, exactly as x509.Certificate.Verify would.
+	Roots         *x509.CertPool
+	Intermediates *x509.CertPool
+
+	// HardFail, if true, rejects the certificate when any
+	// intermediate's revocation status cannot be determined (an
+	// OCSP Unknown response, or no CRL/OCSP reachable), instead of
+	// only rejecting confirmed revocations.
+	HardFail bool
+
+	// OCSPCache is how long a chain element's revocation status is
+	// cached before being re-checked; zero disables caching and
+	// checks every element on every Sign call.
+	OCSPCache time.Duration
 }
 
 // NewSigner creates a new remote Signer directly from a
@@ -57,6 +91,15 @@ func (s *Signer) Sign(req signer.SignRequest) (cert []byte, err error) {
 		// AuthorityKeyId of certTBS.
 		parsedCert, _ := helpers.ParseCertificatePEM(cert)
 
+		if s.verify != nil && parsedCert != nil {
+			if err = s.verifyChain(parsedCert); err != nil {
+				return nil, err
+			}
+		}
+
+		// PrivateKey is never persisted (see signer.SignRequest).
+		req.PrivateKey = ""
+
 		// Create JSON req representation for saving in DB.
 		var reqJSON []byte
 		if reqJSON, err = json.Marshal(req); err != nil {
@@ -90,6 +133,168 @@ func (s *Signer) Sign(req signer.SignRequest) (cert []byte, err error) {
 	return
 }
 
+// Renew asks the remote CFSSL server to rotate cert, presenting it (and
+// key) as a TLS client certificate instead of a CSR or auth token:
+// possession of the still-valid private key is the proof of identity,
+// much like the automated renewal flow used by ACME-style CAs. The
+// server re-issues for the same subject/SANs/key under the profile the
+// certificate was originally issued with (see api/renew), and the
+// result is returned as a PEM-encoded certificate.
+func (s *Signer) Renew(cert *x509.Certificate, key crypto.Signer) (renewed []byte, err error) {
+	p, err := signer.Profile(s, "")
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := helpers.CreateTLSConfig(p.RemoteCAs, nil)
+	tlsConfig.Certificates = []tls.Certificate{
+		{
+			Certificate: [][]byte{cert.Raw},
+			PrivateKey:  key,
+			Leaf:        cert,
+		},
+	}
+
+	result, err := postJSON(tlsConfig, p.RemoteServer, "/api/v1/cfssl/renew", []byte("{}"))
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, ok := result["certificate"].(string)
+	if !ok {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, errors.New("missing certificate in renewal response"))
+	}
+
+	return []byte(certPEM), nil
+}
+
+// postJSON POSTs body to path on the remote server over a connection
+// configured with tlsConfig, and decodes the api.Response envelope's
+// Result as a JSON object. It exists for operations — renewal, and
+// sign variants that ask for extra material alongside the certificate
+// — that need response fields the api/client Sign/Info helpers don't
+// expose.
+func postJSON(tlsConfig *tls.Config, server, path string, body []byte) (map[string]interface{}, error) {
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := httpClient.Post("https://"+server+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.ClientHTTPError, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse api.Response
+	if err = json.Unmarshal(respBody, &apiResponse); err != nil {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+	if !apiResponse.Success || apiResponse.Result == nil {
+		if len(apiResponse.Errors) > 0 {
+			return nil, errors.New(apiResponse.Errors[0].Message)
+		}
+		return nil, errors.New("remote: request failed")
+	}
+
+	result, ok := apiResponse.Result.(map[string]interface{})
+	if !ok {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, errors.New("unexpected result format"))
+	}
+	return result, nil
+}
+
+// SignResult carries a freshly issued certificate together with the
+// CA's currently active trust bundle, as returned by a single Sign
+// round trip when SignWithBundle is used.
+type SignResult struct {
+	Certificate []byte
+	Bundle      []byte
+}
+
+// SignWithBundle sends a sign request to the remote server, like Sign,
+// but additionally asks it to include its currently active root CA
+// bundle (and any cross-signed intermediates) in the response. Unlike
+// Sign, it posts straight to the profile's sign endpoint: it does not
+// authenticate through the profile's RemoteProvider, does not apply the
+// request modifier, does not run the SetVerifyOptions chain check, and
+// does not record the certificate in certdb. This lets a node
+// bootstrapping to a fresh CA obtain its identity certificate and the
+// trust anchor in the same round trip, instead of configuring the root
+// out-of-band, and makes cross-signed root rotation transparent:
+// clients always end up trusting whatever bundle the CA currently
+// advertises.
+func (s *Signer) SignWithBundle(req signer.SignRequest) (*SignResult, error) {
+	p, err := signer.Profile(s, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	req.ReturnBundle = true
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+
+	result, err := postJSON(helpers.CreateTLSConfig(p.RemoteCAs, p.ClientCert), p.RemoteServer, "/api/v1/cfssl/sign", jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, ok := result["certificate"].(string)
+	if !ok {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, errors.New("missing certificate in response"))
+	}
+
+	// root_ca_bundle is only populated when the server has a
+	// RootBundlePath configured for this profile; its absence isn't
+	// an error, just an empty trust bundle.
+	bundlePEM, _ := result["root_ca_bundle"].(string)
+
+	return &SignResult{Certificate: []byte(certPEM), Bundle: []byte(bundlePEM)}, nil
+}
+
+// SignPKCS12 sends a sign request to the remote server, like Sign, but
+// asks it to package the newly issued certificate, keyPEM (the
+// requesting client's private key, PEM-encoded), and the
+// root/intermediate chain into a password-protected PKCS#12 (PFX)
+// blob, returning the raw p12 bytes. Like SignWithBundle, it posts
+// straight to the sign endpoint, without RemoteProvider
+// authentication, the request modifier, the SetVerifyOptions chain
+// check, or a certdb record.
+// This lets non-Go consumers such as Java keystores, Windows
+// certificate stores, and browsers ingest freshly issued material
+// without additional tooling.
+func (s *Signer) SignPKCS12(req signer.SignRequest, keyPEM []byte, password string) ([]byte, error) {
+	p, err := signer.Profile(s, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	req.BundleFormat = "pkcs12"
+	req.BundlePassword = password
+	req.PrivateKey = string(keyPEM)
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, err)
+	}
+
+	result, err := postJSON(helpers.CreateTLSConfig(p.RemoteCAs, p.ClientCert), p.RemoteServer, "/api/v1/cfssl/sign", jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	p12B64, ok := result["pkcs12"].(string)
+	if !ok {
+		return nil, cferr.Wrap(cferr.APIClientError, cferr.JSONError, errors.New("missing pkcs12 bundle in response"))
+	}
+
+	return base64.StdEncoding.DecodeString(p12B64)
+}
+
 // Info sends an info request to the remote CFSSL server, receiving an
 // Resp struct or an error in response.
 func (s *Signer) Info(req info.Req) (resp *info.Resp, err error) {
@@ -139,6 +344,89 @@ func (s *Signer) remoteOp(req interface{}, profile, target string) (resp interfa
 	return
 }
 
+// SetVerifyOptions turns on revocation-aware chain validation: after
+// each Sign, the returned leaf is chained up to opts.Roots (via
+// opts.Intermediates) and every intermediate in that chain is checked
+// against cfssl/revoke (CRL + OCSP, per RFC 5280). Sign refuses to
+// persist to certdb or return the certificate if any element is
+// revoked, or if an element's status is Unknown and opts.HardFail is
+// set.
+func (s *Signer) SetVerifyOptions(opts VerifyOptions) {
+	s.verify = &opts
+}
+
+// verifyChain builds a chain from leaf using the configured roots and
+// intermediates, then walks every intermediate in each candidate chain
+// through cfssl/revoke.
+func (s *Signer) verifyChain(leaf *x509.Certificate) error {
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         s.verify.Roots,
+		Intermediates: s.verify.Intermediates,
+	})
+	if err != nil {
+		return cferr.Wrap(cferr.CertificateError, cferr.VerifyFailed, err)
+	}
+
+	for _, chain := range chains {
+		// chain[0] is the leaf just issued and chain[len(chain)-1] is
+		// the trusted root; only the intermediates between them need a
+		// revocation check. Each intermediate's issuer is the next one
+		// up the chain.
+		for i := 1; i < len(chain)-1; i++ {
+			cert, issuer := chain[i], chain[i+1]
+
+			revoked, ok := s.revokeCache.check(cert, issuer, s.verify.OCSPCache)
+			if revoked {
+				return cferr.New(cferr.CertificateError, cferr.CertificateRevoked)
+			}
+			if !ok && s.verify.HardFail {
+				return cferr.Wrap(cferr.CertificateError, cferr.VerifyFailed,
+					errors.New("revocation status of an intermediate could not be determined"))
+			}
+		}
+	}
+	return nil
+}
+
+// revocationCache memoizes cfssl/revoke lookups for OCSPCache, so a
+// chain with the same intermediates isn't re-checked on every Sign
+// call.
+type revocationCache struct {
+	mu      sync.Mutex
+	entries map[string]revocationCacheEntry
+}
+
+type revocationCacheEntry struct {
+	revoked, ok bool
+	checkedAt   time.Time
+}
+
+func (c *revocationCache) check(cert, issuer *x509.Certificate, ttl time.Duration) (revoked, ok bool) {
+	key := hex.EncodeToString(cert.SubjectKeyId) + "|" + cert.SerialNumber.String()
+
+	if ttl > 0 {
+		c.mu.Lock()
+		if entry, found := c.entries[key]; found && time.Since(entry.checkedAt) < ttl {
+			c.mu.Unlock()
+			return entry.revoked, entry.ok
+		}
+		c.mu.Unlock()
+	}
+
+	revoked, ok = revoke.Check(cert, issuer)
+
+	if ttl > 0 {
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[string]revocationCacheEntry)
+		}
+		c.entries[key] = revocationCacheEntry{revoked: revoked, ok: ok, checkedAt: time.Now()}
+		c.mu.Unlock()
+	}
+
+	return revoked, ok
+}
+
 // SigAlgo returns the RSA signer's signature algorithm.
 func (s *Signer) SigAlgo() x509.SignatureAlgorithm {
 	// TODO: implement this as a remote info call
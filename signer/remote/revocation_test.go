@@ -0,0 +1,95 @@
+package remote
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// unrevokedCert has no CRL or OCSP endpoints, so revoke.Check resolves
+// it to (revoked=false, ok=false) without any network access.
+func unrevokedCert(t *testing.T, serial int64) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "intermediate"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestRevocationCacheServesWithinTTL(t *testing.T) {
+	var c revocationCache
+	cert := unrevokedCert(t, 1)
+
+	// Seed a stale-looking but still-within-TTL entry that disagrees
+	// with what a live revoke.Check lookup would return,
+	// so a cache hit is distinguishable from a live recheck.
+	c.entries = map[string]revocationCacheEntry{
+		keyFor(cert): {revoked: true, ok: true, checkedAt: time.Now()},
+	}
+
+	revoked, ok := c.check(cert, cert, time.Hour)
+	if !revoked || !ok {
+		t.Errorf("expected cached entry (revoked=true, ok=true) to be served within TTL, got (revoked=%v, ok=%v)", revoked, ok)
+	}
+}
+
+func TestRevocationCacheRechecksAfterTTL(t *testing.T) {
+	var c revocationCache
+	cert := unrevokedCert(t, 2)
+
+	c.entries = map[string]revocationCacheEntry{
+		keyFor(cert): {revoked: true, ok: true, checkedAt: time.Now().Add(-time.Hour)},
+	}
+
+	// The seeded entry is older than the 1-minute TTL, so check must
+	// fall through to a live lookup; since cert has no CRL/OCSP
+	// endpoints, that lookup resolves to (false, false).
+	revoked, ok := c.check(cert, cert, time.Minute)
+	if revoked || ok {
+		t.Errorf("expected expired cache entry to be ignored, got (revoked=%v, ok=%v)", revoked, ok)
+	}
+}
+
+func TestRevocationCacheDisabledWithZeroTTL(t *testing.T) {
+	var c revocationCache
+	cert := unrevokedCert(t, 3)
+
+	c.entries = map[string]revocationCacheEntry{
+		keyFor(cert): {revoked: true, ok: true, checkedAt: time.Now()},
+	}
+
+	// ttl == 0 disables caching entirely, so even a fresh entry must
+	// be bypassed in favor of a live lookup.
+	revoked, ok := c.check(cert, cert, 0)
+	if revoked || ok {
+		t.Errorf("expected ttl=0 to bypass the cache, got (revoked=%v, ok=%v)", revoked, ok)
+	}
+	if len(c.entries) != 1 {
+		t.Errorf("expected ttl=0 to leave the cache untouched, got %d entries", len(c.entries))
+	}
+}
+
+func keyFor(cert *x509.Certificate) string {
+	return hex.EncodeToString(cert.SubjectKeyId) + "|" + cert.SerialNumber.String()
+}
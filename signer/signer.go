@@ -0,0 +1,87 @@
+// Package signer implements certificate signing for CFSSL: both the
+// local and remote flavors share the SignRequest format and Signer
+// interface defined here.
+package signer
+
+import (
+	"crypto/x509"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/cfssl/certdb"
+	"github.com/cloudflare/cfssl/config"
+	cferr "github.com/cloudflare/cfssl/errors"
+	"github.com/cloudflare/cfssl/info"
+)
+
+// Subject contains the information that should be used to override
+// the subject information when signing a certificate.
+type Subject struct {
+	CN    string   `json:"CN,omitempty"`
+	Names []string `json:"names,omitempty"`
+}
+
+// SignRequest is the request passed to a Signer to issue a
+// certificate.
+type SignRequest struct {
+	Hosts     []string  `json:"hosts,omitempty"`
+	Request   string    `json:"certificate_request"`
+	Subject   *Subject  `json:"subject,omitempty"`
+	Profile   string    `json:"profile"`
+	Label     string    `json:"label"`
+	Serial    *big.Int  `json:"serial,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	NotAfter  time.Time `json:"not_after,omitempty"`
+
+	// ReturnBundle asks the server to include its currently active
+	// root CA bundle (config.SigningProfile.RootBundlePath) in the
+	// response, alongside the signed certificate.
+	ReturnBundle bool `json:"return_bundle,omitempty"`
+
+	// BundleFormat, when set to "pkcs12", asks the server to package
+	// the certificate, chain, and PrivateKey into a PKCS#12 (PFX)
+	// blob protected by BundlePassword, returned base64-encoded.
+	BundleFormat   string `json:"bundle_format,omitempty"`
+	BundlePassword string `json:"bundle_password,omitempty"`
+
+	// PrivateKey is the PEM-encoded private key matching Request,
+	// supplied only so the server can fold it into a PKCS#12 bundle;
+	// it is never persisted.
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+// Signer is the interface both the local and remote signing
+// implementations fulfill.
+type Signer interface {
+	Info(info.Req) (*info.Resp, error)
+	Sign(req SignRequest) (cert []byte, err error)
+	SigAlgo() x509.SignatureAlgorithm
+	SetPolicy(*config.Signing)
+	SetDBAccessor(certdb.Accessor)
+	GetDBAccessor() certdb.Accessor
+	Policy() *config.Signing
+	SetReqModifier(func(*http.Request, []byte))
+}
+
+// Profile selects the named signing profile from s's policy, or its
+// Default profile when profileName is empty.
+func Profile(s Signer, profileName string) (*config.SigningProfile, error) {
+	policy := s.Policy()
+	if policy == nil {
+		return nil, cferr.New(cferr.PolicyError, cferr.InvalidPolicy)
+	}
+
+	if profileName == "" {
+		if policy.Default == nil {
+			return nil, cferr.New(cferr.PolicyError, cferr.InvalidPolicy)
+		}
+		return policy.Default, nil
+	}
+
+	p, ok := policy.Profiles[profileName]
+	if !ok || p == nil {
+		return nil, cferr.New(cferr.PolicyError, cferr.InvalidPolicy)
+	}
+	return p, nil
+}